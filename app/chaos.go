@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"path"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ChaosRule describes the fault injection to apply to requests whose
+// path matches RoutePattern (a path.Match glob, e.g. "/api/*"). Only the
+// non-zero fields take effect, so a rule can combine injections (e.g.
+// added latency plus a non-zero ErrorRate) or just use one.
+type ChaosRule struct {
+	RoutePattern    string  `json:"route_pattern"`
+	LatencyMode     string  `json:"latency_mode,omitempty"` // "fixed" | "exponential"
+	LatencyMs       int     `json:"latency_ms,omitempty"`
+	ErrorRate       float64 `json:"error_rate,omitempty"` // 0..1 chance of a synthetic 5xx
+	CPUBurnSeconds  int     `json:"cpu_burn_seconds,omitempty"`
+	MemoryBalloonMB int     `json:"memory_balloon_mb,omitempty"`
+}
+
+// ChaosConfig is the full chaos state, swapped atomically by the admin
+// API. Unhealthy is separate from the per-route Rules because it flips
+// the health registry rather than something request-scoped.
+type ChaosConfig struct {
+	Enabled   bool        `json:"enabled"`
+	Unhealthy bool        `json:"unhealthy"`
+	Rules     []ChaosRule `json:"rules"`
+}
+
+var chaosState atomic.Pointer[ChaosConfig]
+
+func init() {
+	chaosState.Store(&ChaosConfig{})
+}
+
+var chaosInjectionsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "podinfo_chaos_injections_total",
+		Help: "Total number of chaos injections applied, by injection type and route",
+	},
+	[]string{"type", "route"},
+)
+
+// matchingChaosRule returns the first enabled rule whose RoutePattern
+// matches reqPath, if any.
+func matchingChaosRule(reqPath string) *ChaosRule {
+	cfg := chaosState.Load()
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+
+	for i := range cfg.Rules {
+		rule := &cfg.Rules[i]
+		if matched, err := path.Match(rule.RoutePattern, reqPath); err == nil && matched {
+			return rule
+		}
+	}
+	return nil
+}
+
+// chaosMiddleware applies whichever injection the active ChaosConfig
+// specifies for the request's route, ahead of the handler running.
+func (a *App) chaosMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rule := matchingChaosRule(r.URL.Path)
+		if rule == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if rule.LatencyMs > 0 {
+			injectChaosLatency(rule)
+			chaosInjectionsTotal.WithLabelValues("latency", r.URL.Path).Inc()
+		}
+
+		if rule.CPUBurnSeconds > 0 {
+			burnCPU(time.Duration(rule.CPUBurnSeconds) * time.Second)
+			chaosInjectionsTotal.WithLabelValues("cpu_burn", r.URL.Path).Inc()
+		}
+
+		if rule.MemoryBalloonMB > 0 {
+			balloonMemory(rule.MemoryBalloonMB)
+			chaosInjectionsTotal.WithLabelValues("memory_balloon", r.URL.Path).Inc()
+		}
+
+		if rule.ErrorRate > 0 && rand.Float64() < rule.ErrorRate {
+			chaosInjectionsTotal.WithLabelValues("error", r.URL.Path).Inc()
+			http.Error(w, "chaos: injected failure", http.StatusInternalServerError)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func injectChaosLatency(rule *ChaosRule) {
+	mean := time.Duration(rule.LatencyMs) * time.Millisecond
+	if rule.LatencyMode == "exponential" {
+		time.Sleep(time.Duration(rand.ExpFloat64() * float64(mean)))
+		return
+	}
+	time.Sleep(mean)
+}
+
+// burnCPU spins a tight loop for duration, pegging a core to simulate
+// CPU pressure for HPA/alerting drills.
+func burnCPU(duration time.Duration) {
+	deadline := time.Now().Add(duration)
+	for time.Now().Before(deadline) {
+	}
+}
+
+// balloonMemory allocates and touches sizeMB of memory so it's actually
+// committed, holds it briefly, then lets it go: enough to exercise
+// memory-pressure alerts without leaking for the life of the process.
+func balloonMemory(sizeMB int) {
+	buf := make([]byte, sizeMB*1024*1024)
+	for i := range buf {
+		buf[i] = 1
+	}
+	time.Sleep(2 * time.Second)
+}
+
+// chaosHealthCheck backs the "chaos" liveness/readiness check: it fails
+// whenever an operator has set ChaosConfig.Unhealthy, so SRE drills can
+// validate that failing health checks actually page someone.
+func chaosHealthCheck(ctx context.Context) error {
+	cfg := chaosState.Load()
+	if cfg != nil && cfg.Enabled && cfg.Unhealthy {
+		return fmt.Errorf("chaos: forced unhealthy via /api/chaos")
+	}
+	return nil
+}
+
+func registerChaosHealthChecks(registry *HealthRegistry) {
+	registry.Register(Check{Name: "chaos", Kind: KindLiveness, Fn: chaosHealthCheck})
+	registry.Register(Check{Name: "chaos", Kind: KindReadiness, Fn: chaosHealthCheck})
+}
+
+// chaosAuthMiddleware gates the admin chaos API behind the same bearer
+// token used elsewhere for API auth, read from the live Secrets so a
+// rotated APIKey takes effect without a restart.
+func (a *App) chaosAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secrets := getSecrets()
+		if secrets == nil || secrets.APIKey == "" {
+			http.Error(w, "chaos API is not configured", http.StatusServiceUnavailable)
+			return
+		}
+
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(secrets.APIKey)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// chaosHandler serves the admin chaos API: GET returns the current
+// config, POST replaces it wholesale.
+func (a *App) chaosHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(chaosState.Load())
+	case http.MethodPost:
+		var cfg ChaosConfig
+		if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+			http.Error(w, fmt.Sprintf("invalid chaos config: %v", err), http.StatusBadRequest)
+			return
+		}
+		chaosState.Store(&cfg)
+
+		loggerFromContext(r.Context()).Info("chaos config updated",
+			"enabled", cfg.Enabled, "unhealthy", cfg.Unhealthy, "rules", len(cfg.Rules))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(cfg)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// chaosStatusHandler is an unauthenticated, read-only summary so
+// dashboards and smoke tests can tell chaos is active without needing
+// the admin token.
+func (a *App) chaosStatusHandler(w http.ResponseWriter, r *http.Request) {
+	cfg := chaosState.Load()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"enabled":    cfg != nil && cfg.Enabled,
+		"rule_count": len(cfg.Rules),
+		"timestamp":  time.Now().Format(time.RFC3339),
+	})
+}
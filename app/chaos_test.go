@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestMatchingChaosRule(t *testing.T) {
+	dataRule := ChaosRule{RoutePattern: "/api/*", LatencyMs: 100}
+
+	tests := []struct {
+		name    string
+		cfg     *ChaosConfig
+		path    string
+		wantHit bool
+	}{
+		{"disabled config matches nothing", &ChaosConfig{Enabled: false, Rules: []ChaosRule{dataRule}}, "/api/data", false},
+		{"enabled config matches glob", &ChaosConfig{Enabled: true, Rules: []ChaosRule{dataRule}}, "/api/data", true},
+		{"enabled config, non-matching path", &ChaosConfig{Enabled: true, Rules: []ChaosRule{dataRule}}, "/healthz", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chaosState.Store(tt.cfg)
+			got := matchingChaosRule(tt.path)
+			if (got != nil) != tt.wantHit {
+				t.Errorf("matchingChaosRule(%q) = %v, want hit=%v", tt.path, got, tt.wantHit)
+			}
+		})
+	}
+
+	chaosState.Store(&ChaosConfig{})
+}
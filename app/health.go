@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CheckKind groups a Check by which probe endpoint should run it.
+type CheckKind string
+
+const (
+	KindLiveness  CheckKind = "liveness"
+	KindReadiness CheckKind = "readiness"
+	KindStartup   CheckKind = "startup"
+)
+
+// CheckFunc reports a subsystem's health. A non-nil error marks the
+// check failed; the error text is surfaced in the probe response.
+type CheckFunc func(ctx context.Context) error
+
+// Check is a single named, kind-tagged health probe.
+type Check struct {
+	Name string
+	Kind CheckKind
+	Fn   CheckFunc
+}
+
+// CheckResult is one Check's outcome, as returned in the /healthz,
+// /readyz, and /startupz JSON bodies.
+type CheckResult struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HealthRegistry lets subsystems register named Checks and lets the
+// /healthz, /readyz, and /startupz handlers run them by kind.
+type HealthRegistry struct {
+	mu     sync.RWMutex
+	checks []Check
+}
+
+func NewHealthRegistry() *HealthRegistry {
+	return &HealthRegistry{}
+}
+
+func (h *HealthRegistry) Register(c Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, c)
+}
+
+// Run executes every registered Check of the given kind and reports
+// whether all of them passed, along with each individual result. ctx is
+// the probe request's context, and Checks that do network I/O (e.g.
+// awsSecretsManagerReachableCheck, databaseReachableCheck) pass it
+// straight through to their dial/API calls, so an aborted probe request
+// actually cancels the in-flight dependency check instead of leaking it.
+func (h *HealthRegistry) Run(ctx context.Context, kind CheckKind) (bool, []CheckResult) {
+	h.mu.RLock()
+	checks := make([]Check, len(h.checks))
+	copy(checks, h.checks)
+	h.mu.RUnlock()
+
+	allOK := true
+	results := make([]CheckResult, 0, len(checks))
+
+	for _, c := range checks {
+		if c.Kind != kind {
+			continue
+		}
+
+		start := time.Now()
+		err := c.Fn(ctx)
+		latency := time.Since(start)
+
+		result := CheckResult{Name: c.Name, Kind: string(c.Kind), LatencyMs: latency.Milliseconds()}
+		statusValue := 1.0
+		if err != nil {
+			allOK = false
+			result.Status = "error"
+			result.Error = err.Error()
+			statusValue = 0
+		} else {
+			result.Status = "ok"
+		}
+
+		healthCheckStatus.WithLabelValues(c.Name, string(c.Kind)).Set(statusValue)
+		healthCheckDuration.WithLabelValues(c.Name, string(c.Kind)).Observe(latency.Seconds())
+		results = append(results, result)
+	}
+
+	return allOK, results
+}
+
+// cachedCheck wraps fn so it only actually runs once per ttl; callers in
+// between get the last result. This keeps an expensive dependency probe
+// (e.g. a round trip to AWS Secrets Manager) off the hot path of every
+// kubelet probe tick.
+func cachedCheck(ttl time.Duration, fn CheckFunc) CheckFunc {
+	var mu sync.Mutex
+	var lastRun time.Time
+	var lastErr error
+
+	return func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if time.Since(lastRun) < ttl {
+			return lastErr
+		}
+		lastErr = fn(ctx)
+		lastRun = time.Now()
+		return lastErr
+	}
+}
+
+// registerBuiltinHealthChecks wires up the checks every deployment gets
+// for free: secret load status, the configured secret backend's
+// reachability, the database if one is configured, and basic disk/memory
+// thresholds.
+func registerBuiltinHealthChecks(registry *HealthRegistry, config *Config) {
+	registry.Register(Check{
+		Name: "secrets-loaded",
+		Kind: KindStartup,
+		Fn:   secretsLoadedCheck,
+	})
+	registry.Register(Check{
+		Name: "secrets-loaded",
+		Kind: KindReadiness,
+		Fn:   secretsLoadedCheck,
+	})
+
+	if config.SecretsBackend == "aws" || config.SecretsBackend == "" {
+		registry.Register(Check{
+			Name: "aws-secrets-manager",
+			Kind: KindReadiness,
+			Fn:   cachedCheck(30*time.Second, awsSecretsManagerReachableCheck(config)),
+		})
+	}
+
+	if secrets := getSecrets(); secrets != nil && secrets.DatabaseURL != "" {
+		registry.Register(Check{
+			Name: "database",
+			Kind: KindReadiness,
+			Fn:   cachedCheck(15*time.Second, databaseReachableCheck(secrets.DatabaseURL)),
+		})
+	}
+
+	registry.Register(Check{
+		Name: "disk",
+		Kind: KindLiveness,
+		Fn:   diskThresholdCheck("/", getEnvInt("DISK_THRESHOLD_PERCENT", 90)),
+	})
+	registry.Register(Check{
+		Name: "memory",
+		Kind: KindLiveness,
+		Fn:   memoryThresholdCheck(getEnvInt("MEMORY_THRESHOLD_MB", 512)),
+	})
+}
+
+func secretsLoadedCheck(ctx context.Context) error {
+	if getSecrets() == nil {
+		return fmt.Errorf("secrets have not been loaded yet")
+	}
+	return nil
+}
+
+func awsSecretsManagerReachableCheck(config *Config) CheckFunc {
+	return func(ctx context.Context) error {
+		sess, err := session.NewSession(&aws.Config{Region: aws.String(config.AWSRegion)})
+		if err != nil {
+			return fmt.Errorf("failed to create AWS session: %w", err)
+		}
+		instrumentAWSSession(sess)
+
+		svc := secretsmanager.New(sess)
+		_, err = svc.ListSecretsWithContext(ctx, &secretsmanager.ListSecretsInput{
+			MaxResults: aws.Int64(1),
+		})
+		if err != nil {
+			return fmt.Errorf("AWS Secrets Manager unreachable: %w", err)
+		}
+		return nil
+	}
+}
+
+func databaseReachableCheck(databaseURL string) CheckFunc {
+	return func(ctx context.Context) error {
+		u, err := url.Parse(databaseURL)
+		if err != nil {
+			return fmt.Errorf("invalid DATABASE_URL: %w", err)
+		}
+
+		host := u.Host
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			host = net.JoinHostPort(host, "5432")
+		}
+
+		dialer := net.Dialer{Timeout: 3 * time.Second}
+		conn, err := dialer.DialContext(ctx, "tcp", host)
+		if err != nil {
+			return fmt.Errorf("database unreachable at %s: %w", host, err)
+		}
+		return conn.Close()
+	}
+}
+
+func diskThresholdCheck(path string, maxUsedPercent int) CheckFunc {
+	return func(ctx context.Context) error {
+		var stat syscall.Statfs_t
+		if err := syscall.Statfs(path, &stat); err != nil {
+			return fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+
+		total := stat.Blocks * uint64(stat.Bsize)
+		free := stat.Bfree * uint64(stat.Bsize)
+		if total == 0 {
+			return nil
+		}
+
+		usedPercent := int(float64(total-free) / float64(total) * 100)
+		if usedPercent > maxUsedPercent {
+			return fmt.Errorf("disk usage %d%% exceeds threshold %d%%", usedPercent, maxUsedPercent)
+		}
+		return nil
+	}
+}
+
+func memoryThresholdCheck(maxAllocMB int) CheckFunc {
+	return func(ctx context.Context) error {
+		var mem runtime.MemStats
+		runtime.ReadMemStats(&mem)
+
+		allocMB := int(mem.Alloc / 1024 / 1024)
+		if allocMB > maxAllocMB {
+			return fmt.Errorf("heap allocation %dMB exceeds threshold %dMB", allocMB, maxAllocMB)
+		}
+		return nil
+	}
+}
+
+// healthResponse writes the worst status across results as the HTTP
+// status code, alongside the per-check breakdown.
+func healthResponse(w http.ResponseWriter, overallOK bool, results []CheckResult) {
+	status := http.StatusOK
+	overall := "healthy"
+	if !overallOK {
+		status = http.StatusServiceUnavailable
+		overall = "unhealthy"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    overall,
+		"timestamp": time.Now().Format(time.RFC3339),
+		"checks":    results,
+	})
+}
+
+var (
+	healthCheckStatus = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "podinfo_health_check_status",
+			Help: "Result of the most recent run of a health check (1 = ok, 0 = error)",
+		},
+		[]string{"name", "kind"},
+	)
+
+	healthCheckDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "podinfo_health_check_duration_seconds",
+			Help:    "Duration of health check execution",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"name", "kind"},
+	)
+)
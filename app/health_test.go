@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestHealthRegistryRun(t *testing.T) {
+	registry := NewHealthRegistry()
+	registry.Register(Check{Name: "ok-check", Kind: KindReadiness, Fn: func(ctx context.Context) error {
+		return nil
+	}})
+	registry.Register(Check{Name: "failing-check", Kind: KindReadiness, Fn: func(ctx context.Context) error {
+		return fmt.Errorf("boom")
+	}})
+	registry.Register(Check{Name: "other-kind", Kind: KindLiveness, Fn: func(ctx context.Context) error {
+		return fmt.Errorf("should not run for readiness")
+	}})
+
+	ok, results := registry.Run(context.Background(), KindReadiness)
+
+	if ok {
+		t.Fatal("Run() reported ok, want false because of failing-check")
+	}
+	if len(results) != 2 {
+		t.Fatalf("Run() returned %d results, want 2 (liveness check should be excluded)", len(results))
+	}
+
+	byName := make(map[string]CheckResult, len(results))
+	for _, r := range results {
+		byName[r.Name] = r
+	}
+
+	if got := byName["ok-check"].Status; got != "ok" {
+		t.Errorf("ok-check status = %q, want \"ok\"", got)
+	}
+	if got := byName["failing-check"].Status; got != "error" {
+		t.Errorf("failing-check status = %q, want \"error\"", got)
+	}
+	if byName["failing-check"].Error == "" {
+		t.Error("failing-check result has no Error message")
+	}
+}
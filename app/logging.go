@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+)
+
+// ctxKey is a typed context key so correlation IDs and loggers stashed on
+// the request context can't collide with keys set by other packages (the
+// old code used a bare string, "correlationID").
+type ctxKey int
+
+const (
+	correlationIDKey ctxKey = iota
+	loggerKey
+)
+
+// logger is the base structured logger; tracingMiddleware derives a
+// per-request child from it via .With so every log line emitted while
+// handling a request carries the same correlation_id (and trace/span IDs
+// when the request is sampled).
+var logger *slog.Logger
+
+// newLogger builds a JSON slog.Logger at the level named by levelName
+// ("debug", "info", "warn", "error"; defaults to info).
+func newLogger(levelName string) *slog.Logger {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(levelName)); err != nil {
+		level = slog.LevelInfo
+	}
+
+	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level})
+	return slog.New(handler)
+}
+
+// correlationIDFromContext returns the request's correlation ID, or
+// "unknown" if none was set (e.g. code running outside a request).
+func correlationIDFromContext(ctx context.Context) string {
+	id, ok := ctx.Value(correlationIDKey).(string)
+	if !ok {
+		return "unknown"
+	}
+	return id
+}
+
+// loggerFromContext returns the logger carrying the request's
+// correlation ID, falling back to the base logger for code that runs
+// without a request context (startup, background refreshers).
+func loggerFromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+		return l
+	}
+	return logger
+}
+
+// withCorrelationID returns a context carrying correlationID and a
+// logger pre-bound to it, for code paths (startup, background secret
+// refresh) that aren't serving an HTTP request but still want their log
+// lines to be traceable back to a single run.
+func withCorrelationID(ctx context.Context, correlationID string) context.Context {
+	ctx = context.WithValue(ctx, correlationIDKey, correlationID)
+	return context.WithValue(ctx, loggerKey, logger.With("correlation_id", correlationID))
+}
+
+// statusRecorder wraps a ResponseWriter so loggingMiddleware can capture
+// the status code a handler actually wrote; the stdlib ResponseWriter
+// has no getter for it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (a *App) loggingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		start := time.Now()
+		next.ServeHTTP(rec, r)
+		duration := time.Since(start)
+
+		loggerFromContext(r.Context()).Info("http request",
+			"correlation_id", correlationIDFromContext(r.Context()),
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"duration_ms", duration.Milliseconds(),
+			"remote_addr", r.RemoteAddr,
+			"user_agent", r.UserAgent(),
+		)
+	})
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJitteredInterval(t *testing.T) {
+	base := 5 * time.Minute
+	min := base - base/5
+	max := base + base/5
+
+	for i := 0; i < 100; i++ {
+		got := jitteredInterval(base)
+		if got < min || got > max {
+			t.Fatalf("jitteredInterval(%s) = %s, want within [%s, %s]", base, got, min, max)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// setupTracing configures the global TracerProvider and W3C propagator
+// from config. The returned func flushes buffered spans and must be
+// called during graceful shutdown.
+func setupTracing(ctx context.Context, config *Config) (func(context.Context) error, error) {
+	exporter, err := newSpanExporter(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create span exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			semconv.ServiceName("podinfo"),
+			semconv.ServiceVersion(config.Version),
+			semconv.DeploymentEnvironment(config.Environment),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OTel resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(newSampler(config)),
+	)
+
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return tp.Shutdown, nil
+}
+
+// newSpanExporter returns an OTLP/gRPC exporter pointed at
+// OTEL_EXPORTER_OTLP_ENDPOINT, or a stdout exporter when that's unset so
+// local development still produces visible traces.
+func newSpanExporter(ctx context.Context, config *Config) (sdktrace.SpanExporter, error) {
+	if config.OtelExporterEndpoint == "" {
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	}
+
+	return otlptracegrpc.New(ctx,
+		otlptracegrpc.WithEndpoint(config.OtelExporterEndpoint),
+		otlptracegrpc.WithInsecure(),
+	)
+}
+
+func newSampler(config *Config) sdktrace.Sampler {
+	ratio := sdktrace.TraceIDRatioBased(config.OtelTracesSamplerArg)
+
+	switch config.OtelTracesSampler {
+	case "always_on":
+		return sdktrace.AlwaysSample()
+	case "always_off":
+		return sdktrace.NeverSample()
+	case "traceidratio":
+		return ratio
+	default: // "parentbased_traceidratio" and unrecognized values
+		return sdktrace.ParentBased(ratio)
+	}
+}
+
+// tracingMiddleware wraps the request in an otelhttp server span named
+// after the matched mux route (not the raw path, for the same
+// cardinality reason as the Prometheus labels), extracting W3C
+// traceparent/tracestate from the incoming request. It then takes over
+// correlation ID assignment: the client-supplied X-Correlation-ID wins
+// if present, otherwise the trace ID becomes the correlation ID, and
+// both are bound into the request's logger.
+func (a *App) tracingMiddleware(next http.Handler) http.Handler {
+	instrumented := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span := trace.SpanFromContext(r.Context())
+
+		correlationID := r.Header.Get("X-Correlation-ID")
+		if correlationID == "" && span.SpanContext().HasTraceID() {
+			correlationID = span.SpanContext().TraceID().String()
+		}
+
+		w.Header().Set("X-Correlation-ID", correlationID)
+		next.ServeHTTP(w, r.WithContext(withTraceContext(r.Context(), span, correlationID)))
+	})
+
+	return otelhttp.NewHandler(instrumented, "podinfo", otelhttp.WithSpanNameFormatter(routeSpanName))
+}
+
+func routeSpanName(operation string, r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if tmpl, err := route.GetPathTemplate(); err == nil {
+			return r.Method + " " + tmpl
+		}
+	}
+	return r.Method + " unknown"
+}
+
+// withTraceContext stores the correlation ID and a logger annotated with
+// it plus the current trace/span IDs (when sampled) on ctx.
+func withTraceContext(ctx context.Context, span trace.Span, correlationID string) context.Context {
+	fields := []any{"correlation_id", correlationID}
+	if sc := span.SpanContext(); sc.HasTraceID() {
+		fields = append(fields, "trace_id", sc.TraceID().String(), "span_id", sc.SpanID().String())
+	}
+
+	ctx = context.WithValue(ctx, correlationIDKey, correlationID)
+	return context.WithValue(ctx, loggerKey, logger.With(fields...))
+}
+
+// instrumentAWSSession adds handlers to sess that start a client span for
+// each AWS API call and end it when the call completes, so calls made
+// through it (secret fetches, reachability checks) appear as child spans
+// of whichever span is active on the calling context. There's no otelaws
+// contrib package for this: that instrumentation only ships for
+// aws-sdk-go-v2, and this app uses the v1, session-based aws-sdk-go.
+func instrumentAWSSession(sess *session.Session) {
+	tracer := otel.Tracer("aws-sdk-go")
+
+	sess.Handlers.Send.PushFrontNamed(request.NamedHandler{
+		Name: "podinfo.otel.send",
+		Fn: func(req *request.Request) {
+			ctx, _ := tracer.Start(req.Context(), req.ClientInfo.ServiceName+"."+req.Operation.Name,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					attribute.String("aws.service", req.ClientInfo.ServiceName),
+					attribute.String("aws.operation", req.Operation.Name),
+				),
+			)
+			req.SetContext(ctx)
+		},
+	})
+
+	sess.Handlers.Complete.PushBackNamed(request.NamedHandler{
+		Name: "podinfo.otel.complete",
+		Fn: func(req *request.Request) {
+			span := trace.SpanFromContext(req.Context())
+			if req.Error != nil {
+				span.RecordError(req.Error)
+				span.SetStatus(codes.Error, req.Error.Error())
+			}
+			span.End()
+		},
+	})
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestNewSampler(t *testing.T) {
+	tests := []struct {
+		name        string
+		samplerName string
+		wantDesc    string
+	}{
+		{"always on", "always_on", sdktrace.AlwaysSample().Description()},
+		{"always off", "always_off", sdktrace.NeverSample().Description()},
+		{"ratio", "traceidratio", sdktrace.TraceIDRatioBased(0.5).Description()},
+		{"parent based default", "parentbased_traceidratio", sdktrace.ParentBased(sdktrace.TraceIDRatioBased(0.5)).Description()},
+		{"unrecognized falls back to parent based", "bogus", sdktrace.ParentBased(sdktrace.TraceIDRatioBased(0.5)).Description()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{OtelTracesSampler: tt.samplerName, OtelTracesSamplerArg: 0.5}
+			got := newSampler(config).Description()
+			if got != tt.wantDesc {
+				t.Errorf("newSampler(%q) = %q, want %q", tt.samplerName, got, tt.wantDesc)
+			}
+		})
+	}
+}